@@ -0,0 +1,105 @@
+package command
+
+import (
+	"context"
+	"encoding/gob"
+	"sync"
+	"time"
+)
+
+// asyncJob pairs a Command queued via HandleAsync/HandleAsyncContext with the
+// context it was submitted with, so the worker can honor cancellation and
+// deadlines set by the caller. attempt counts handling attempts made so far,
+// starting at 1, and is used to evaluate the bus's RetryPolicy. None of this
+// ever reaches a Queue implementation directly - jobQueue below carries it
+// alongside the durable Enqueue/Dequeue call instead, so a Queue (and its
+// Codec, for a durable one) only ever has to handle the plain Command.
+type asyncJob struct {
+	ctx        context.Context
+	cmd        Command
+	attempt    int
+	enqueuedAt time.Time
+}
+
+// stopSignal is the Command jobQueue enqueues to wake exactly one blocked
+// worker during Shutdown; it is never a real command a Handler is
+// registered for. It is registered with gob so the default GobCodec can
+// round-trip it through a durable Queue like FileQueue the same as any
+// other Command.
+type stopSignal struct{}
+
+func init() {
+	gob.Register(stopSignal{})
+}
+
+// jobQueue sits between the Bus and the configured Queue, carrying each
+// asyncJob's ctx, attempt and enqueuedAt alongside the durable Enqueue call
+// in an in-memory, per-process record instead of inside the serialized
+// Command. Those fields couldn't survive serialization anyway - ctx embeds
+// a live context.Context, which encoding/gob has no way to encode - and are
+// meaningless in isolation after a restart, so a Command redelivered from a
+// durable Queue without a matching record is rehydrated with
+// context.Background(), attempt 1 and the current time instead.
+type jobQueue struct {
+	queue Queue
+
+	mu   sync.Mutex
+	meta []jobMeta
+}
+
+// jobMeta is the bookkeeping for one asyncJob not handed to the Queue.
+type jobMeta struct {
+	ctx        context.Context
+	attempt    int
+	enqueuedAt time.Time
+}
+
+// newJobQueue wraps queue, the durable or in-memory backend configured on
+// the Bus.
+func newJobQueue(queue Queue) *jobQueue {
+	return &jobQueue{queue: queue}
+}
+
+// Enqueue stores job.cmd on the underlying Queue and records the rest of
+// job in-memory, in lock-step, so the Dequeue that receives job.cmd back
+// can reassemble the same asyncJob.
+func (jq *jobQueue) Enqueue(ctx context.Context, job asyncJob) error {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+	if err := jq.queue.Enqueue(ctx, job.cmd); err != nil {
+		return err
+	}
+	jq.meta = append(jq.meta, jobMeta{ctx: job.ctx, attempt: job.attempt, enqueuedAt: job.enqueuedAt})
+	return nil
+}
+
+// Dequeue blocks until a Command is available from the underlying Queue and
+// reassembles it into an asyncJob. A dequeued stopSignal is returned as the
+// zero asyncJob, whose nil cmd the worker recognizes as its own shutdown
+// sentinel.
+func (jq *jobQueue) Dequeue(ctx context.Context) (asyncJob, Ack, error) {
+	cmd, ack, err := jq.queue.Dequeue(ctx)
+	if err != nil {
+		return asyncJob{}, nil, err
+	}
+	if _, ok := cmd.(stopSignal); ok {
+		jq.popMeta()
+		return asyncJob{}, ack, nil
+	}
+	meta := jq.popMeta()
+	return asyncJob{ctx: meta.ctx, cmd: cmd, attempt: meta.attempt, enqueuedAt: meta.enqueuedAt}, ack, nil
+}
+
+// popMeta removes and returns the oldest recorded jobMeta, or a default one
+// if none is recorded - the case for a command redelivered from a durable
+// Queue that was never Enqueued through this jobQueue instance.
+func (jq *jobQueue) popMeta() jobMeta {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+	if len(jq.meta) == 0 {
+		return jobMeta{ctx: context.Background(), attempt: 1, enqueuedAt: time.Now()}
+	}
+	m := jq.meta[0]
+	jq.meta = jq.meta[1:]
+	return m
+}
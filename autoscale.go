@@ -0,0 +1,118 @@
+package command
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// AutoScalePolicy configures dynamic worker pool sizing: the bus starts
+// with MinWorkers and, while active, scales up to MaxWorkers as async queue
+// depth grows, scaling back down to MinWorkers once the queue has sat idle
+// for a while. Workers up to MinWorkers are permanent; only workers spawned
+// above that floor are ever scaled back down.
+type AutoScalePolicy struct {
+	MinWorkers int
+	MaxWorkers int
+	// SampleInterval is how often the supervisor samples queue depth.
+	SampleInterval time.Duration
+	// HighWatermark is the queue depth that, sustained for SustainedWindow,
+	// triggers spawning one additional worker.
+	HighWatermark int
+	// SustainedWindow is how long depth must stay above HighWatermark
+	// before a worker is spawned.
+	SustainedWindow time.Duration
+	// IdleTimeout is how long the queue must stay empty before a worker
+	// above MinWorkers is scaled back down.
+	IdleTimeout time.Duration
+}
+
+// AutoScale switches the bus from a fixed-size worker pool to one that
+// scales between policy.MinWorkers and policy.MaxWorkers based on observed
+// queue pressure, taking over from WorkerPoolSize. It can only be set
+// *before* the bus is initialized.
+func (bus *Bus) AutoScale(policy AutoScalePolicy) {
+	if !bus.isInitialized() {
+		bus.autoScalePolicy = &policy
+	}
+}
+
+// spawnWorker starts a worker reading from bus.jobs using ctx to bound its
+// blocking Dequeue call: a Background ctx makes the worker permanent, while
+// a cancelable one lets the auto-scaling supervisor scale it back down.
+func (bus *Bus) spawnWorker(ctx context.Context) {
+	bus.workerUp()
+	go bus.worker(bus.jobs, bus.closed, ctx)
+}
+
+// scaleUp spawns one worker above the permanent minimum, tracking its
+// cancel func so a later scaleDown can stop this exact worker.
+func (bus *Bus) scaleUp() {
+	ctx, cancel := context.WithCancel(context.Background())
+	bus.scaleMu.Lock()
+	bus.scaleWorkers = append(bus.scaleWorkers, cancel)
+	bus.scaleMu.Unlock()
+	bus.spawnWorker(ctx)
+}
+
+// scaleDown cancels the most recently spawned scaled-up worker. Canceling
+// its context only interrupts its next (or current, if idle) Dequeue call,
+// never an in-flight Handler, since Handler invocations run on the separate
+// context merged in runAsync.
+func (bus *Bus) scaleDown() bool {
+	bus.scaleMu.Lock()
+	defer bus.scaleMu.Unlock()
+	n := len(bus.scaleWorkers)
+	if n == 0 {
+		return false
+	}
+	cancel := bus.scaleWorkers[n-1]
+	bus.scaleWorkers = bus.scaleWorkers[:n-1]
+	cancel()
+	return true
+}
+
+// supervise samples queue depth on policy.SampleInterval and scales the
+// worker pool between policy.MinWorkers and policy.MaxWorkers, stopping
+// once the bus starts shutting down so it never races Shutdown's own
+// worker bookkeeping.
+func (bus *Bus) supervise(policy AutoScalePolicy) {
+	ticker := time.NewTicker(policy.SampleInterval)
+	defer ticker.Stop()
+
+	requiredSamples := 1
+	if policy.SampleInterval > 0 && policy.SustainedWindow > policy.SampleInterval {
+		requiredSamples = int(policy.SustainedWindow / policy.SampleInterval)
+	}
+
+	aboveWatermark := 0
+	lastNonZero := time.Now()
+
+	for range ticker.C {
+		if bus.isShuttingDown() {
+			return
+		}
+
+		depth := bus.queue.Len()
+		current := int(atomic.LoadUint32(bus.workers))
+
+		if depth > policy.HighWatermark {
+			aboveWatermark++
+		} else {
+			aboveWatermark = 0
+		}
+		if depth != 0 {
+			lastNonZero = time.Now()
+		}
+
+		switch {
+		case aboveWatermark >= requiredSamples && current < policy.MaxWorkers:
+			bus.scaleUp()
+			aboveWatermark = 0
+		case depth == 0 && current > policy.MinWorkers && time.Since(lastNonZero) >= policy.IdleTimeout:
+			if bus.scaleDown() {
+				lastNonZero = time.Now()
+			}
+		}
+	}
+}
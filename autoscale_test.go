@@ -0,0 +1,105 @@
+package command
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeAutoScaleQueue is a Queue whose reported depth is set directly by the
+// test, independent of what (if anything) is actually enqueued, so tests
+// can drive supervise's sampling without a real producer/consumer.
+type fakeAutoScaleQueue struct {
+	ch chan Command
+
+	mu    sync.Mutex
+	depth int
+}
+
+func newFakeAutoScaleQueue() *fakeAutoScaleQueue {
+	return &fakeAutoScaleQueue{ch: make(chan Command)}
+}
+
+func (q *fakeAutoScaleQueue) Enqueue(ctx context.Context, cmd Command) error {
+	select {
+	case q.ch <- cmd:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *fakeAutoScaleQueue) Dequeue(ctx context.Context) (Command, Ack, error) {
+	select {
+	case cmd := <-q.ch:
+		return cmd, func(error) error { return nil }, nil
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+func (q *fakeAutoScaleQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.depth
+}
+
+func (q *fakeAutoScaleQueue) setDepth(d int) {
+	q.mu.Lock()
+	q.depth = d
+	q.mu.Unlock()
+}
+
+func (q *fakeAutoScaleQueue) Close() error { return nil }
+
+// TestAutoScale_IdleTimeoutMeasuredSinceQueueEmptied verifies that a queue
+// which sat at a low, sub-watermark depth for longer than IdleTimeout
+// before finally emptying still waits a full IdleTimeout after becoming
+// empty before scaling down, instead of treating the earlier sub-watermark
+// stretch as if it were already idle time.
+func TestAutoScale_IdleTimeoutMeasuredSinceQueueEmptied(t *testing.T) {
+	q := newFakeAutoScaleQueue()
+	bus := NewBus()
+	bus.Queue(q)
+	bus.AutoScale(AutoScalePolicy{
+		MinWorkers:      1,
+		MaxWorkers:      3,
+		SampleInterval:  10 * time.Millisecond,
+		HighWatermark:   5,
+		SustainedWindow: 10 * time.Millisecond,
+		IdleTimeout:     80 * time.Millisecond,
+	})
+	hdl := HandlerFunc[noopCmd](func(ctx context.Context, cmd noopCmd) error { return nil })
+	if err := bus.Initialize(hdl); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	defer bus.Shutdown(context.Background())
+
+	// Sit at a low, sub-watermark depth for well over IdleTimeout before
+	// the queue ever becomes empty.
+	q.setDepth(1)
+	time.Sleep(150 * time.Millisecond)
+
+	// Force a worker above MinWorkers so there is something to scale back
+	// down.
+	bus.scaleUp()
+	time.Sleep(20 * time.Millisecond)
+	if current := atomic.LoadUint32(bus.workers); current < 2 {
+		t.Fatalf("expected a worker above MinWorkers, got %d", current)
+	}
+
+	// The queue only becomes empty now - scale-down must wait a full
+	// IdleTimeout from this point.
+	q.setDepth(0)
+	time.Sleep(40 * time.Millisecond)
+	if current := atomic.LoadUint32(bus.workers); current < 2 {
+		t.Fatal("scaled down before a full IdleTimeout elapsed since the queue became empty")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if current := atomic.LoadUint32(bus.workers); current != 1 {
+		t.Fatalf("expected scale-down to MinWorkers once IdleTimeout elapsed, got %d workers", current)
+	}
+}
@@ -1,28 +1,50 @@
 package command
 
 import (
+	"context"
 	"errors"
+	"reflect"
 	"runtime"
+	"sync"
 	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Bus is the only struct exported and required for the command bus usage.
 // The Bus should be instantiated using the NewBus function.
 type Bus struct {
-	workerPoolSize     int
-	queueBuffer        int
-	initialized        *uint32
-	shuttingDown       *uint32
-	workers            *uint32
-	handlers           []Handler
-	errorHandlers      []ErrorHandler
-	asyncCommandsQueue chan Command
-	closed             chan bool
+	workerPoolSize    int
+	queueBuffer       int
+	initialized       *uint32
+	shuttingDown      *uint32
+	workers           *uint32
+	handlers          map[reflect.Type]Handler
+	errorHandlers     []ErrorHandler
+	middleware        []Middleware
+	pipeline          HandleFunc
+	metrics           *metricsRecorder
+	retryPolicy       *RetryPolicy
+	deadLetterHandler DeadLetterHandler
+	retrySchedule     chan *retryItem
+	queue             Queue
+	jobs              *jobQueue
+	tracer            trace.Tracer
+	meter             metric.Meter
+	autoScalePolicy   *AutoScalePolicy
+	scaleMu           sync.Mutex
+	scaleWorkers      []context.CancelFunc
+	closed            chan bool
+	shutdownCtx       context.Context
+	shutdownCancel    context.CancelFunc
 }
 
 // NewBus instantiates the Bus struct.
 // The Initialization of the Bus is performed separately (Initialize function) for dependency injection purposes.
 func NewBus() *Bus {
+	shutdownCtx, shutdownCancel := context.WithCancel(context.Background())
 	return &Bus{
 		workerPoolSize: runtime.GOMAXPROCS(0),
 		queueBuffer:    100,
@@ -31,6 +53,8 @@ func NewBus() *Bus {
 		workers:        new(uint32),
 		errorHandlers:  make([]ErrorHandler, 0),
 		closed:         make(chan bool),
+		shutdownCtx:    shutdownCtx,
+		shutdownCancel: shutdownCancel,
 	}
 }
 
@@ -61,41 +85,129 @@ func (bus *Bus) ErrorHandlers(hdls ...ErrorHandler) {
 	}
 }
 
-// Initialize the command bus.
-func (bus *Bus) Initialize(hdls ...Handler) {
-	if bus.initialize() {
-		bus.handlers = hdls
-		bus.asyncCommandsQueue = make(chan Command, bus.queueBuffer)
+// RetryPolicy may optionally be provided to retry async commands whose
+// Handler returns a retryable error, using exponential backoff between
+// attempts. It has no effect on synchronous Handle/HandleContext calls.
+// It can only be adjusted *before* the bus is initialized.
+func (bus *Bus) RetryPolicy(policy RetryPolicy) {
+	if !bus.isInitialized() {
+		bus.retryPolicy = &policy
+	}
+}
+
+// DeadLetterHandler may optionally be provided to receive async commands
+// that exhausted their RetryPolicy, instead of having them silently dropped.
+// It can only be adjusted *before* the bus is initialized.
+func (bus *Bus) DeadLetterHandler(hdl DeadLetterHandler) {
+	if !bus.isInitialized() {
+		bus.deadLetterHandler = hdl
+	}
+}
+
+// Queue may optionally be provided to back async commands with a durable
+// store - e.g. a FileQueue - instead of the default InMemoryQueue, so
+// commands still queued at process exit are not lost.
+// It can only be adjusted *before* the bus is initialized.
+func (bus *Bus) Queue(queue Queue) {
+	if !bus.isInitialized() {
+		bus.queue = queue
+	}
+}
+
+// Initialize the command bus, building a dispatch table keyed by the
+// concrete Command type each handler reports via Typed. It returns
+// ErrHandlerConflict if two handlers are registered for the same type.
+func (bus *Bus) Initialize(hdls ...Typed) error {
+	if !bus.initialize() {
+		return nil
+	}
+	handlers := make(map[reflect.Type]Handler, len(hdls))
+	for _, hdl := range hdls {
+		cmdType := hdl.CommandType()
+		if _, exists := handlers[cmdType]; exists {
+			atomic.CompareAndSwapUint32(bus.initialized, 1, 0)
+			return ErrHandlerConflict
+		}
+		handlers[cmdType] = hdl
+	}
+	bus.handlers = handlers
+	bus.pipeline = bus.chain()
+	if bus.tracer != nil {
+		bus.pipeline = tracingMiddleware(bus.tracer)(bus.pipeline)
+	}
+	if bus.meter != nil {
+		mw, err := otelMetricsMiddleware(bus.meter)
+		if err != nil {
+			atomic.CompareAndSwapUint32(bus.initialized, 1, 0)
+			return err
+		}
+		bus.pipeline = mw(bus.pipeline)
+	}
+	if bus.queue == nil {
+		bus.queue = NewInMemoryQueue(bus.queueBuffer)
+	}
+	bus.jobs = newJobQueue(bus.queue)
+	if bus.autoScalePolicy != nil {
+		for i := 0; i < bus.autoScalePolicy.MinWorkers; i++ {
+			bus.spawnWorker(context.Background())
+		}
+		go bus.supervise(*bus.autoScalePolicy)
+	} else {
 		for i := 0; i < bus.workerPoolSize; i++ {
-			bus.workerUp()
-			go bus.worker(bus.asyncCommandsQueue, bus.closed)
+			bus.spawnWorker(context.Background())
 		}
-		atomic.CompareAndSwapUint32(bus.shuttingDown, 1, 0)
 	}
+	if bus.retryPolicy != nil {
+		bus.retrySchedule = make(chan *retryItem, bus.queueBuffer)
+		go bus.retryScheduler(bus.retrySchedule, bus.jobs, bus.shutdownCtx.Done())
+	}
+	atomic.CompareAndSwapUint32(bus.shuttingDown, 1, 0)
+	return nil
 }
 
-// HandleAsync the command using the workers asynchronously.
+// HandleAsync the command using the workers asynchronously, using
+// context.Background() as the command's context. Equivalent to calling
+// HandleAsyncContext(context.Background(), cmd).
 func (bus *Bus) HandleAsync(cmd Command) error {
-	if err := bus.isValid(cmd); err != nil {
+	return bus.HandleAsyncContext(context.Background(), cmd)
+}
+
+// HandleAsyncContext enqueues the command to be handled by the workers
+// asynchronously. ctx is carried alongside the command and observed by the
+// worker: if ctx is done before a worker picks the command up, it is
+// dropped and every ErrorHandler is invoked with ctx.Err() instead.
+func (bus *Bus) HandleAsyncContext(ctx context.Context, cmd Command) error {
+	if err := bus.isValid(ctx, cmd); err != nil {
 		return err
 	}
-	bus.asyncCommandsQueue <- cmd
-	return nil
+	if _, ok := bus.handlers[reflect.TypeOf(cmd)]; !ok {
+		bus.error(ctx, cmd, ErrNoHandler)
+		return ErrNoHandler
+	}
+	return bus.jobs.Enqueue(ctx, asyncJob{ctx: ctx, cmd: cmd, attempt: 1, enqueuedAt: time.Now()})
 }
 
-// Handle the command synchronously.
+// Handle the command synchronously, using context.Background() as the
+// command's context. Equivalent to calling HandleContext(context.Background(), cmd).
 func (bus *Bus) Handle(cmd Command) error {
-	if err := bus.isValid(cmd); err != nil {
+	return bus.HandleContext(context.Background(), cmd)
+}
+
+// HandleContext handles the command synchronously, passing ctx through to
+// the registered Handler so it can honor cancellation and deadlines.
+func (bus *Bus) HandleContext(ctx context.Context, cmd Command) error {
+	if err := bus.isValid(ctx, cmd); err != nil {
 		return err
 	}
-	return bus.handle(cmd)
+	return bus.pipeline(ctx, cmd)
 }
 
-// Shutdown the command bus gracefully.
-// *Async commands handled while shutting down will be disregarded*.
-func (bus *Bus) Shutdown() {
+// Shutdown the command bus gracefully: it stops accepting new commands,
+// drains the async queue until ctx expires, then cancels any commands still
+// in flight by canceling the context passed to their Handler.
+func (bus *Bus) Shutdown(ctx context.Context) {
 	if atomic.CompareAndSwapUint32(bus.shuttingDown, 0, 1) {
-		bus.shutdown()
+		bus.shutdown(ctx)
 	}
 }
 
@@ -113,24 +225,101 @@ func (bus *Bus) isShuttingDown() bool {
 	return atomic.LoadUint32(bus.shuttingDown) == 1
 }
 
-func (bus *Bus) worker(asyncCommandsQueue <-chan Command, closed chan<- bool) {
-	for cmd := range asyncCommandsQueue {
-		if cmd == nil {
-			break
+// worker repeatedly dequeues and handles commands using ctx to bound its
+// Dequeue call: a canceled ctx (only ever the case for workers spawned by
+// the auto-scaling supervisor above the permanent minimum) makes it exit
+// quietly, decrementing the worker count itself since nothing is waiting on
+// bus.closed for it. A nil-Command sentinel, sent only by Shutdown, makes
+// it exit the same way but additionally signal bus.closed so Shutdown's
+// drain loop can account for it.
+func (bus *Bus) worker(jobs *jobQueue, closed chan<- bool, ctx context.Context) {
+	for {
+		job, ack, err := jobs.Dequeue(ctx)
+		if err != nil {
+			bus.workerDown()
+			return
 		}
-		_ = bus.handle(cmd)
+		if job.cmd == nil {
+			ack(nil)
+			bus.workerDown()
+			closed <- true
+			return
+		}
+		bus.runAsync(job)
+		ack(nil)
 	}
-	closed <- true
 }
 
-func (bus *Bus) handle(cmd Command) error {
-	for _, hdl := range bus.handlers {
-		if err := hdl.Handle(cmd); err != nil {
-			bus.error(cmd, err)
-			return err
+// runAsync handles a single dequeued job, dropping it without invoking its
+// handler if its context was already done by the time a worker picked it
+// up, and otherwise merging it with the bus's shutdown context so that a
+// Shutdown deadline cancels the in-flight handler too.
+func (bus *Bus) runAsync(job asyncJob) {
+	select {
+	case <-job.ctx.Done():
+		bus.error(job.ctx, job.cmd, job.ctx.Err())
+		return
+	default:
+	}
+	ctx, cancel := mergeContext(job.ctx, bus.shutdownCtx)
+	defer cancel()
+	ctx = withAsyncMeta(ctx, asyncMeta{queueWait: time.Since(job.enqueuedAt), attempt: job.attempt})
+	err := bus.pipeline(ctx, job.cmd)
+	if err == nil || bus.retryPolicy == nil {
+		return
+	}
+	bus.retryOrDeadLetter(job, err)
+}
+
+// retryOrDeadLetter is consulted after a failed async attempt when a
+// RetryPolicy is configured: it re-schedules the command if attempts remain
+// and the error is retryable, otherwise forwards it to the DeadLetterHandler.
+func (bus *Bus) retryOrDeadLetter(job asyncJob, err error) {
+	policy := *bus.retryPolicy
+	if job.attempt < policy.MaxAttempts && policy.retryable(err) {
+		bus.retrySchedule <- &retryItem{
+			when: time.Now().Add(policy.delay(job.attempt)),
+			job:  asyncJob{ctx: job.ctx, cmd: job.cmd, attempt: job.attempt + 1, enqueuedAt: time.Now()},
 		}
+		return
+	}
+	if bus.deadLetterHandler != nil {
+		bus.deadLetterHandler.Handle(job.ctx, job.cmd, job.attempt, err)
 	}
-	return nil
+}
+
+// mergeContext returns a context that is done when either a or b is done,
+// carrying whichever's error triggered it first.
+func mergeContext(a, b context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(a)
+	stop := context.AfterFunc(b, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// dispatch looks up and invokes the Handler registered for cmd's concrete
+// type, reporting to every ErrorHandler whenever it does not return nil -
+// including a panicking Handler, recovered here and converted to a
+// *PanicError, so an ErrorHandler learns about that failure mode too
+// instead of the panic unwinding straight past it.
+func (bus *Bus) dispatch(ctx context.Context, cmd Command) (err error) {
+	hdl, ok := bus.handlers[reflect.TypeOf(cmd)]
+	if !ok {
+		bus.error(ctx, cmd, ErrNoHandler)
+		return ErrNoHandler
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r}
+			bus.error(ctx, cmd, err)
+		}
+	}()
+	if err = hdl.Handle(ctx, cmd); err != nil {
+		bus.error(ctx, cmd, err)
+	}
+	return err
 }
 
 func (bus *Bus) workerUp() {
@@ -141,37 +330,68 @@ func (bus *Bus) workerDown() {
 	atomic.AddUint32(bus.workers, ^uint32(0))
 }
 
-func (bus *Bus) shutdown() {
-	for atomic.LoadUint32(bus.workers) > 0 {
-		bus.asyncCommandsQueue <- nil
-		<-bus.closed
-		bus.workerDown()
+// shutdown drains the async queue, stopping one worker at a time as each
+// acknowledges a stopSignal (each worker decrements the shared counter
+// itself), until either every worker has stopped or ctx expires - whichever
+// comes first is what bounds shutdown's own blocking time, matching
+// Shutdown's documented deadline. bus.shutdownCancel is always called,
+// unblocking any in-flight handler that honors its context. The queue is
+// only ever closed once the drain goroutine has stopped touching it - on
+// the ctx-wins path that goroutine is still given a chance to unwind (it
+// exits as soon as its own select observes ctx.Done()) but shutdown itself
+// does not wait around for that; a detached goroutine closes the queue
+// once it does.
+func (bus *Bus) shutdown(ctx context.Context) {
+	defer bus.shutdownCancel()
+
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		for atomic.LoadUint32(bus.workers) > 0 {
+			_ = bus.jobs.Enqueue(context.Background(), asyncJob{cmd: stopSignal{}})
+			select {
+			case <-bus.closed:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-drained:
+		atomic.CompareAndSwapUint32(bus.initialized, 1, 0)
+		_ = bus.queue.Close()
+	case <-ctx.Done():
+		atomic.CompareAndSwapUint32(bus.initialized, 1, 0)
+		go func() {
+			<-drained
+			_ = bus.queue.Close()
+		}()
 	}
-	atomic.CompareAndSwapUint32(bus.initialized, 1, 0)
 }
 
-func (bus *Bus) isValid(cmd Command) error {
+func (bus *Bus) isValid(ctx context.Context, cmd Command) error {
 	var err error
 	if cmd == nil {
 		err = errors.New("invalid command")
-		bus.error(cmd, err)
+		bus.error(ctx, cmd, err)
 		return err
 	}
 	if !bus.isInitialized() {
 		err = errors.New("the command bus is not initialized")
-		bus.error(cmd, err)
+		bus.error(ctx, cmd, err)
 		return err
 	}
 	if bus.isShuttingDown() {
 		err = errors.New("the command bus is shutting down")
-		bus.error(cmd, err)
+		bus.error(ctx, cmd, err)
 		return err
 	}
 	return nil
 }
 
-func (bus *Bus) error(qry Command, err error) {
+func (bus *Bus) error(ctx context.Context, cmd Command, err error) {
 	for _, errHdl := range bus.errorHandlers {
-		errHdl.Handle(qry, err)
+		errHdl.Handle(ctx, cmd, err)
 	}
 }
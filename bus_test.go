@@ -0,0 +1,71 @@
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type blockCmd struct{}
+
+type noopCmd struct{}
+
+// TestShutdown_BoundedByContext verifies that Shutdown returns once ctx is
+// done, even while an async command is still in flight and the queue has
+// not fully drained.
+func TestShutdown_BoundedByContext(t *testing.T) {
+	bus := NewBus()
+	bus.WorkerPoolSize(1)
+
+	started := make(chan struct{})
+	hdl := HandlerFunc[blockCmd](func(ctx context.Context, cmd blockCmd) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err := bus.Initialize(hdl); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if err := bus.HandleAsync(blockCmd{}); err != nil {
+		t.Fatalf("HandleAsync: %v", err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		bus.Shutdown(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Shutdown did not return once its context expired")
+	}
+}
+
+// TestShutdown_CancelsShutdownContextOnSuccess verifies that a Shutdown
+// call that drains successfully still cancels the bus's shutdown context,
+// so background goroutines selecting on it (e.g. retryScheduler) exit
+// instead of leaking.
+func TestShutdown_CancelsShutdownContextOnSuccess(t *testing.T) {
+	bus := NewBus()
+	bus.WorkerPoolSize(1)
+	bus.RetryPolicy(RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond})
+
+	hdl := HandlerFunc[noopCmd](func(ctx context.Context, cmd noopCmd) error { return nil })
+	if err := bus.Initialize(hdl); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	bus.Shutdown(ctx)
+
+	if bus.shutdownCtx.Err() == nil {
+		t.Fatal("shutdownCtx was not canceled after a successful Shutdown")
+	}
+}
@@ -0,0 +1,37 @@
+package command
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// Codec serializes a Command so a durable Queue implementation such as
+// FileQueue can persist it.
+type Codec interface {
+	Marshal(cmd Command) ([]byte, error)
+	Unmarshal(data []byte) (Command, error)
+}
+
+// GobCodec is a Codec built on encoding/gob. Every concrete Command type
+// that goes through it must first be registered with gob.Register, the same
+// requirement encoding/gob itself imposes on any value stored in an
+// interface.
+type GobCodec struct{}
+
+// Marshal implements Codec.
+func (GobCodec) Marshal(cmd Command) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&cmd); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal implements Codec.
+func (GobCodec) Unmarshal(data []byte) (Command, error) {
+	var cmd Command
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&cmd); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
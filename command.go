@@ -0,0 +1,12 @@
+package command
+
+// Command marks a type as dispatchable through the Bus. It carries no
+// behaviour of its own; handlers type-assert the concrete type they expect.
+type Command interface{}
+
+// Named may optionally be implemented by a Command to provide a stable name
+// for logging, metrics and error messages that does not depend on the
+// concrete Go type, and therefore survives refactors that rename it.
+type Named interface {
+	CommandName() string
+}
@@ -0,0 +1,11 @@
+package command
+
+import "context"
+
+// ErrorHandler receives any error produced while processing a Command,
+// whether returned by a Handler or raised internally by the Bus (e.g.
+// ErrNoHandler). ctx is the command's context, so an ErrorHandler can tell
+// a genuine failure apart from ctx.Err() after cancellation or a deadline.
+type ErrorHandler interface {
+	Handle(ctx context.Context, cmd Command, err error)
+}
@@ -0,0 +1,11 @@
+package command
+
+import "errors"
+
+// ErrNoHandler is returned by Handle/HandleAsync when no handler was
+// registered for the concrete type of the given Command.
+var ErrNoHandler = errors.New("command: no handler registered for this command type")
+
+// ErrHandlerConflict is returned by Initialize when two handlers are
+// registered for the same Command type.
+var ErrHandlerConflict = errors.New("command: a handler is already registered for this command type")
@@ -0,0 +1,46 @@
+package command
+
+import (
+	"context"
+	"reflect"
+)
+
+// Handler processes a single Command. A Bus is initialized with at most one
+// Handler per concrete Command type. ctx is the context the command was
+// submitted with (HandleContext/HandleAsyncContext), or context.Background()
+// when submitted through Handle/HandleAsync; well-behaved handlers doing
+// non-trivial work should honor ctx.Done().
+type Handler interface {
+	Handle(ctx context.Context, cmd Command) error
+}
+
+// Typed is implemented by handlers that know, without being invoked, which
+// Command type they handle. Initialize uses CommandType to build the
+// dispatch table, so registration no longer requires calling into the
+// handler just to find out what it is for.
+type Typed interface {
+	Handler
+	CommandType() reflect.Type
+}
+
+// HandlerFunc adapts a plain function into a Handler for a specific Command
+// type T, so a handler can be registered without declaring a struct:
+//
+//	bus.Initialize(command.HandlerFunc[CreateUser](createUser))
+type HandlerFunc[T Command] func(ctx context.Context, cmd T) error
+
+// Handle implements Handler by type-asserting cmd to T before calling the
+// underlying function.
+func (f HandlerFunc[T]) Handle(ctx context.Context, cmd Command) error {
+	typed, ok := cmd.(T)
+	if !ok {
+		return ErrNoHandler
+	}
+	return f(ctx, typed)
+}
+
+// CommandType implements Typed, letting Initialize key this handler by the
+// concrete type T without invoking it.
+func (f HandlerFunc[T]) CommandType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
@@ -0,0 +1,86 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type dispatchCmdA struct{}
+type dispatchCmdB struct{}
+
+// TestInitialize_DuplicateRegistrationReturnsErrHandlerConflict verifies
+// that registering two handlers for the same concrete Command type is
+// rejected, rather than silently keeping only one of them.
+func TestInitialize_DuplicateRegistrationReturnsErrHandlerConflict(t *testing.T) {
+	bus := NewBus()
+	first := HandlerFunc[dispatchCmdA](func(ctx context.Context, cmd dispatchCmdA) error { return nil })
+	second := HandlerFunc[dispatchCmdA](func(ctx context.Context, cmd dispatchCmdA) error { return nil })
+
+	err := bus.Initialize(first, second)
+	if !errors.Is(err, ErrHandlerConflict) {
+		t.Fatalf("Initialize returned %v, want ErrHandlerConflict", err)
+	}
+}
+
+// TestInitialize_DuplicateRegistrationLeavesBusUninitialized verifies that
+// a rejected Initialize call can be retried with a corrected handler set,
+// rather than leaving the bus permanently stuck as initialized.
+func TestInitialize_DuplicateRegistrationLeavesBusUninitialized(t *testing.T) {
+	bus := NewBus()
+	first := HandlerFunc[dispatchCmdA](func(ctx context.Context, cmd dispatchCmdA) error { return nil })
+	second := HandlerFunc[dispatchCmdA](func(ctx context.Context, cmd dispatchCmdA) error { return nil })
+
+	if err := bus.Initialize(first, second); !errors.Is(err, ErrHandlerConflict) {
+		t.Fatalf("Initialize returned %v, want ErrHandlerConflict", err)
+	}
+
+	if err := bus.Initialize(first); err != nil {
+		t.Fatalf("Initialize after conflict: %v", err)
+	}
+	defer bus.Shutdown(context.Background())
+
+	if err := bus.Handle(dispatchCmdA{}); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+}
+
+// TestHandle_UnknownCommandReturnsErrNoHandler verifies that dispatching a
+// Command whose concrete type was never registered is rejected with
+// ErrNoHandler instead of being silently dropped or fanned out to an
+// unrelated handler.
+func TestHandle_UnknownCommandReturnsErrNoHandler(t *testing.T) {
+	bus := NewBus()
+	hdl := HandlerFunc[dispatchCmdA](func(ctx context.Context, cmd dispatchCmdA) error { return nil })
+	if err := bus.Initialize(hdl); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	defer bus.Shutdown(context.Background())
+
+	err := bus.Handle(dispatchCmdB{})
+	if !errors.Is(err, ErrNoHandler) {
+		t.Fatalf("Handle returned %v, want ErrNoHandler", err)
+	}
+}
+
+// TestHandle_UnknownCommandReportsErrorHandlers verifies that the
+// ErrNoHandler rejection is, like any other dispatch error, reported
+// through every registered ErrorHandler.
+func TestHandle_UnknownCommandReportsErrorHandlers(t *testing.T) {
+	errHdl := &captureErrorHandler{}
+	bus := NewBus()
+	bus.ErrorHandlers(errHdl)
+	hdl := HandlerFunc[dispatchCmdA](func(ctx context.Context, cmd dispatchCmdA) error { return nil })
+	if err := bus.Initialize(hdl); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	defer bus.Shutdown(context.Background())
+
+	if err := bus.Handle(dispatchCmdB{}); !errors.Is(err, ErrNoHandler) {
+		t.Fatalf("Handle returned %v, want ErrNoHandler", err)
+	}
+
+	if len(errHdl.errs) != 1 || !errors.Is(errHdl.errs[0], ErrNoHandler) {
+		t.Fatalf("ErrorHandlers received %v, want exactly [ErrNoHandler]", errHdl.errs)
+	}
+}
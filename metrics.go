@@ -0,0 +1,82 @@
+package command
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// latencyBucketsMs are the upper bounds, in milliseconds, of the histogram
+// buckets used by Metrics; Stats.LatencyMs carries one extra bucket beyond
+// these for everything slower than the last bound.
+var latencyBucketsMs = []int64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000}
+
+// Stats is a point-in-time snapshot of the counters recorded by Metrics.
+type Stats struct {
+	Handled uint64
+	Failed  uint64
+	// LatencyMs holds one counter per bucket in latencyBucketsMs, plus a
+	// trailing bucket for everything slower than the last bound.
+	LatencyMs []uint64
+}
+
+type metricsRecorder struct {
+	handled uint64
+	failed  uint64
+	buckets []uint64
+}
+
+func newMetricsRecorder() *metricsRecorder {
+	return &metricsRecorder{buckets: make([]uint64, len(latencyBucketsMs)+1)}
+}
+
+func (m *metricsRecorder) observe(d time.Duration, err error) {
+	atomic.AddUint64(&m.handled, 1)
+	if err != nil {
+		atomic.AddUint64(&m.failed, 1)
+	}
+	ms := d.Milliseconds()
+	for i, bound := range latencyBucketsMs {
+		if ms <= bound {
+			atomic.AddUint64(&m.buckets[i], 1)
+			return
+		}
+	}
+	atomic.AddUint64(&m.buckets[len(latencyBucketsMs)], 1)
+}
+
+func (m *metricsRecorder) snapshot() Stats {
+	buckets := make([]uint64, len(m.buckets))
+	for i := range m.buckets {
+		buckets[i] = atomic.LoadUint64(&m.buckets[i])
+	}
+	return Stats{
+		Handled:   atomic.LoadUint64(&m.handled),
+		Failed:    atomic.LoadUint64(&m.failed),
+		LatencyMs: buckets,
+	}
+}
+
+// Metrics returns a Middleware that records handled/failed counts and a
+// latency histogram for every command, readable via Bus.Stats().
+func (bus *Bus) Metrics() Middleware {
+	bus.metrics = newMetricsRecorder()
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, cmd Command) error {
+			start := time.Now()
+			err := next(ctx, cmd)
+			bus.metrics.observe(time.Since(start), err)
+			return err
+		}
+	}
+}
+
+// Stats returns a snapshot of the metrics recorded by the Metrics
+// middleware. Every count in it is zero if Metrics was never registered via
+// Use.
+func (bus *Bus) Stats() Stats {
+	if bus.metrics == nil {
+		return Stats{LatencyMs: make([]uint64, len(latencyBucketsMs)+1)}
+	}
+	return bus.metrics.snapshot()
+}
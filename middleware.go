@@ -0,0 +1,32 @@
+package command
+
+import "context"
+
+// HandleFunc is the shape of the final dispatch step a Middleware wraps:
+// looking up and invoking the Handler registered for cmd's concrete type.
+type HandleFunc func(ctx context.Context, cmd Command) error
+
+// Middleware wraps a HandleFunc with cross-cutting behaviour - logging,
+// metrics, tracing, validation, auth, panic recovery - without the
+// underlying Handler needing to know about it. Middlewares run in the order
+// they were registered with Bus.Use, each wrapping the next.
+type Middleware func(next HandleFunc) HandleFunc
+
+// Use registers middleware to run around every Handle/HandleAsync call.
+// Middleware can only be registered *before* the bus is initialized, and
+// runs in the order given: the first Middleware passed is the outermost.
+func (bus *Bus) Use(mw ...Middleware) {
+	if !bus.isInitialized() {
+		bus.middleware = append(bus.middleware, mw...)
+	}
+}
+
+// chain composes the registered middleware around dispatch, the innermost
+// HandleFunc that actually looks up and invokes the Handler.
+func (bus *Bus) chain() HandleFunc {
+	h := bus.dispatch
+	for i := len(bus.middleware) - 1; i >= 0; i-- {
+		h = bus.middleware[i](h)
+	}
+	return h
+}
@@ -0,0 +1,51 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PanicError wraps the value recovered from a panicking Handler. Callers
+// that need to tell a panic apart from an ordinary error - e.g. tracing -
+// can check for it with errors.As.
+type PanicError struct {
+	Value interface{}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("command: handler panicked: %v", e.Value)
+}
+
+// Recovery returns a Middleware that recovers from a panic in any
+// middleware running inside it in the chain (registered after it) and
+// turns it into a *PanicError; a panic in middleware registered before it
+// is not recovered. dispatch itself already recovers from a panicking Handler
+// and reports it through the bus's ErrorHandlers, so Recovery only needs
+// to guard against a panic in custom middleware placed between it and
+// dispatch, preventing that from silently killing a worker goroutine too.
+func Recovery() Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, cmd Command) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = &PanicError{Value: r}
+				}
+			}()
+			return next(ctx, cmd)
+		}
+	}
+}
+
+// Timeout returns a Middleware that bounds a Handler to d by deriving a
+// context.WithTimeout from the command's context before calling next, so a
+// single slow Handler cannot block a worker indefinitely.
+func Timeout(d time.Duration) Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, cmd Command) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return next(ctx, cmd)
+		}
+	}
+}
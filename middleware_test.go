@@ -0,0 +1,170 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type middlewareOrderCmd struct{}
+
+type panicCmd struct{}
+
+// captureErrorHandler records every error it is handed, for assertions.
+type captureErrorHandler struct {
+	errs []error
+}
+
+func (h *captureErrorHandler) Handle(ctx context.Context, cmd Command, err error) {
+	h.errs = append(h.errs, err)
+}
+
+// TestMiddleware_RunInRegistrationOrderOutermostFirst verifies that the
+// first Middleware passed to Use wraps every other one, so it observes the
+// command before and after all the others do.
+func TestMiddleware_RunInRegistrationOrderOutermostFirst(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next HandleFunc) HandleFunc {
+			return func(ctx context.Context, cmd Command) error {
+				order = append(order, name+":before")
+				err := next(ctx, cmd)
+				order = append(order, name+":after")
+				return err
+			}
+		}
+	}
+
+	bus := NewBus()
+	bus.Use(trace("outer"), trace("inner"))
+	hdl := HandlerFunc[middlewareOrderCmd](func(ctx context.Context, cmd middlewareOrderCmd) error { return nil })
+	if err := bus.Initialize(hdl); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	defer bus.Shutdown(context.Background())
+
+	if err := bus.Handle(middlewareOrderCmd{}); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+
+	want := []string{"outer:before", "inner:before", "inner:after", "outer:after"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+// TestDispatch_HandlerPanicReachesErrorHandlers verifies that a panicking
+// Handler is recovered, converted to a *PanicError, and reported through
+// every registered ErrorHandler - not just turned into a returned error
+// that only the immediate caller sees.
+func TestDispatch_HandlerPanicReachesErrorHandlers(t *testing.T) {
+	errHdl := &captureErrorHandler{}
+	bus := NewBus()
+	bus.ErrorHandlers(errHdl)
+	hdl := HandlerFunc[panicCmd](func(ctx context.Context, cmd panicCmd) error {
+		panic("boom")
+	})
+	if err := bus.Initialize(hdl); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	defer bus.Shutdown(context.Background())
+
+	err := bus.Handle(panicCmd{})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Handle returned %v, want a *PanicError", err)
+	}
+
+	if len(errHdl.errs) != 1 {
+		t.Fatalf("ErrorHandlers received %d errors, want 1", len(errHdl.errs))
+	}
+	if !errors.As(errHdl.errs[0], &panicErr) {
+		t.Fatalf("ErrorHandlers received %v, want a *PanicError", errHdl.errs[0])
+	}
+}
+
+// TestRecovery_CatchesPanicInInnerMiddleware verifies that Recovery still
+// guards against a panic raised by a middleware registered after it (and so
+// running inside it, between Recovery and dispatch), rather than only ever
+// seeing handler panics (which dispatch itself now recovers from before they
+// ever reach Recovery).
+func TestRecovery_CatchesPanicInInnerMiddleware(t *testing.T) {
+	bus := NewBus()
+	bus.Use(Recovery(), func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, cmd Command) error {
+			panic("middleware boom")
+		}
+	})
+	hdl := HandlerFunc[panicCmd](func(ctx context.Context, cmd panicCmd) error { return nil })
+	if err := bus.Initialize(hdl); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	defer bus.Shutdown(context.Background())
+
+	err := bus.Handle(panicCmd{})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Handle returned %v, want a *PanicError", err)
+	}
+}
+
+// TestTimeout_CancelsHandlerContextAfterDuration verifies that Timeout
+// derives a context that is done once d elapses, bounding a Handler that
+// would otherwise block forever on ctx.Done().
+func TestTimeout_CancelsHandlerContextAfterDuration(t *testing.T) {
+	bus := NewBus()
+	bus.Use(Timeout(10 * time.Millisecond))
+	hdl := HandlerFunc[blockCmd](func(ctx context.Context, cmd blockCmd) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err := bus.Initialize(hdl); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	defer bus.Shutdown(context.Background())
+
+	start := time.Now()
+	err := bus.Handle(blockCmd{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Handle returned %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Handle took %v, want it bounded by Timeout's duration", elapsed)
+	}
+}
+
+// TestMetrics_RecordsHandledAndFailedCounts verifies that the Metrics
+// middleware counts a command whose Handler returns an error both as
+// handled and as failed.
+func TestMetrics_RecordsHandledAndFailedCounts(t *testing.T) {
+	bus := NewBus()
+	bus.Use(bus.Metrics())
+	hdl := HandlerFunc[panicCmd](func(ctx context.Context, cmd panicCmd) error {
+		if cmd == (panicCmd{}) {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	if err := bus.Initialize(hdl); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	defer bus.Shutdown(context.Background())
+
+	if err := bus.Handle(panicCmd{}); err == nil {
+		t.Fatal("Handle: expected an error")
+	}
+
+	stats := bus.Stats()
+	if stats.Handled != 1 {
+		t.Fatalf("Stats().Handled = %d, want 1", stats.Handled)
+	}
+	if stats.Failed != 1 {
+		t.Fatalf("Stats().Failed = %d, want 1", stats.Failed)
+	}
+}
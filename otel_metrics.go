@@ -0,0 +1,53 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// WithMeter enables OpenTelemetry metrics: every Handle/HandleAsync call
+// records a handled counter and a duration histogram, labeled by command
+// and outcome. It can only be set *before* the bus is initialized.
+func (bus *Bus) WithMeter(meter metric.Meter) {
+	if !bus.isInitialized() {
+		bus.meter = meter
+	}
+}
+
+// otelMetricsMiddleware records a handled counter and a duration histogram
+// on meter for every command passed through next.
+func otelMetricsMiddleware(meter metric.Meter) (Middleware, error) {
+	handled, err := meter.Int64Counter(
+		"command.handled",
+		metric.WithDescription("Commands handled, labeled by command and outcome."),
+	)
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram(
+		"command.duration",
+		metric.WithDescription("Handler duration."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, cmd Command) error {
+			start := time.Now()
+			err := next(ctx, cmd)
+
+			attrs := metric.WithAttributes(
+				attribute.String("command", commandSpanName(cmd)),
+				attribute.String("outcome", outcome(err)),
+			)
+			handled.Add(ctx, 1, attrs)
+			duration.Record(ctx, time.Since(start).Seconds(), attrs)
+			return err
+		}
+	}, nil
+}
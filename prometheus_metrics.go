@@ -0,0 +1,52 @@
+package command
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// busCollector is a prometheus.Collector exposing the bus's queue depth,
+// active worker count, and the handled/failed counters also available via
+// Stats.
+type busCollector struct {
+	bus *Bus
+
+	queueDepth    *prometheus.Desc
+	activeWorkers *prometheus.Desc
+	handled       *prometheus.Desc
+	failed        *prometheus.Desc
+}
+
+// MetricsCollector returns a prometheus.Collector exposing the bus's queue
+// depth, active worker count, and handled/failed counters, for registration
+// with a prometheus.Registry. Call it any time after Initialize.
+func (bus *Bus) MetricsCollector() prometheus.Collector {
+	return &busCollector{
+		bus:           bus,
+		queueDepth:    prometheus.NewDesc("command_queue_depth", "Number of commands currently queued for async handling.", nil, nil),
+		activeWorkers: prometheus.NewDesc("command_active_workers", "Number of async worker goroutines currently running.", nil, nil),
+		handled:       prometheus.NewDesc("command_handled_total", "Total commands handled.", nil, nil),
+		failed:        prometheus.NewDesc("command_failed_total", "Total commands that returned an error.", nil, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *busCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.queueDepth
+	ch <- c.activeWorkers
+	ch <- c.handled
+	ch <- c.failed
+}
+
+// Collect implements prometheus.Collector.
+func (c *busCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.bus.queue != nil {
+		ch <- prometheus.MustNewConstMetric(c.queueDepth, prometheus.GaugeValue, float64(c.bus.queue.Len()))
+	}
+	ch <- prometheus.MustNewConstMetric(c.activeWorkers, prometheus.GaugeValue, float64(atomic.LoadUint32(c.bus.workers)))
+
+	stats := c.bus.Stats()
+	ch <- prometheus.MustNewConstMetric(c.handled, prometheus.CounterValue, float64(stats.Handled))
+	ch <- prometheus.MustNewConstMetric(c.failed, prometheus.CounterValue, float64(stats.Failed))
+}
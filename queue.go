@@ -0,0 +1,27 @@
+package command
+
+import "context"
+
+// Queue is the backing store for async commands. The Bus enqueues to it
+// from HandleAsyncContext and a pool of workers dequeue from it in a loop;
+// swapping the implementation - e.g. for FileQueue - lets async commands
+// survive a process restart instead of being held only in an in-process
+// channel. The default, set by Initialize when none was provided via
+// Bus.Queue, is InMemoryQueue.
+type Queue interface {
+	// Enqueue stores cmd for later delivery.
+	Enqueue(ctx context.Context, cmd Command) error
+	// Dequeue blocks until a Command is available, ctx is done, or the
+	// queue is closed. The returned Ack must be called once the command
+	// has been handled: ack(nil) marks it delivered, while a non-nil error
+	// (or never calling Ack) leaves it for redelivery, giving a durable
+	// implementation at-least-once delivery semantics.
+	Dequeue(ctx context.Context) (Command, Ack, error)
+	// Len reports the number of commands currently queued.
+	Len() int
+	// Close releases any resources held by the queue.
+	Close() error
+}
+
+// Ack acknowledges a Command returned by Queue.Dequeue.
+type Ack func(err error) error
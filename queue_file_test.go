@@ -0,0 +1,185 @@
+package command
+
+import (
+	"context"
+	"encoding/gob"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fileQueueCmd struct{ Value int }
+
+func init() {
+	gob.Register(fileQueueCmd{})
+}
+
+// TestFileQueue_EnqueueDequeueRoundTrip verifies that a plain Command
+// Enqueued onto a FileQueue can be Dequeued back out through GobCodec
+// without the caller registering anything beyond the Command type itself.
+func TestFileQueue_EnqueueDequeueRoundTrip(t *testing.T) {
+	q, err := NewFileQueue(filepath.Join(t.TempDir(), "queue.log"), GobCodec{})
+	if err != nil {
+		t.Fatalf("NewFileQueue: %v", err)
+	}
+	defer q.Close()
+
+	want := fileQueueCmd{Value: 42}
+	if err := q.Enqueue(context.Background(), want); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	got, ack, err := q.Dequeue(ctx)
+	if err != nil {
+		t.Fatalf("Dequeue: %v", err)
+	}
+	if got.(fileQueueCmd) != want {
+		t.Fatalf("Dequeue returned %+v, want %+v", got, want)
+	}
+	if err := ack(nil); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+}
+
+// TestFileQueue_ConcurrentDequeueClaimDisjointRecords reproduces the
+// out-of-the-box case - the bus's default WorkerPoolSize spawns more than
+// one worker - where a second Dequeue call races an earlier one that
+// hasn't acked yet. Both calls must claim distinct records; neither may
+// see the other's still-unacked record.
+func TestFileQueue_ConcurrentDequeueClaimDisjointRecords(t *testing.T) {
+	q, err := NewFileQueue(filepath.Join(t.TempDir(), "queue.log"), GobCodec{})
+	if err != nil {
+		t.Fatalf("NewFileQueue: %v", err)
+	}
+	defer q.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := q.Enqueue(ctx, fileQueueCmd{Value: 1}); err != nil {
+		t.Fatalf("Enqueue 1: %v", err)
+	}
+
+	gotA, ackA, err := q.Dequeue(ctx)
+	if err != nil {
+		t.Fatalf("Dequeue A: %v", err)
+	}
+
+	if err := q.Enqueue(ctx, fileQueueCmd{Value: 2}); err != nil {
+		t.Fatalf("Enqueue 2: %v", err)
+	}
+
+	gotB, ackB, err := q.Dequeue(ctx)
+	if err != nil {
+		t.Fatalf("Dequeue B: %v", err)
+	}
+
+	if gotA.(fileQueueCmd) == gotB.(fileQueueCmd) {
+		t.Fatalf("Dequeue A and B both claimed %+v", gotA)
+	}
+	if err := ackA(nil); err != nil {
+		t.Fatalf("ack A: %v", err)
+	}
+	if err := ackB(nil); err != nil {
+		t.Fatalf("ack B: %v", err)
+	}
+	if got, want := q.Len(), 0; got != want {
+		t.Fatalf("Len() = %d, want %d", got, want)
+	}
+}
+
+// TestFileQueue_ConcurrentDequeueStress enqueues a batch of records and
+// drains them with many concurrent workers, verifying every record is
+// claimed exactly once regardless of ack timing.
+func TestFileQueue_ConcurrentDequeueStress(t *testing.T) {
+	q, err := NewFileQueue(filepath.Join(t.TempDir(), "queue.log"), GobCodec{})
+	if err != nil {
+		t.Fatalf("NewFileQueue: %v", err)
+	}
+	defer q.Close()
+
+	const n = 200
+	ctx := context.Background()
+	for i := 0; i < n; i++ {
+		if err := q.Enqueue(ctx, fileQueueCmd{Value: i}); err != nil {
+			t.Fatalf("Enqueue %d: %v", i, err)
+		}
+	}
+
+	var (
+		mu   sync.Mutex
+		seen = make(map[int]int)
+		wg   sync.WaitGroup
+	)
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				dctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+				cmd, ack, err := q.Dequeue(dctx)
+				cancel()
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				seen[cmd.(fileQueueCmd).Value]++
+				mu.Unlock()
+				_ = ack(nil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != n {
+		t.Fatalf("got %d distinct records, want %d", len(seen), n)
+	}
+	for v, count := range seen {
+		if count != 1 {
+			t.Fatalf("record %d claimed %d times, want 1", v, count)
+		}
+	}
+}
+
+// TestBus_HandleAsync_WithFileQueue reproduces the original failure: a Bus
+// backed by a FileQueue/GobCodec must only ever hand the durable Queue a
+// plain, registered Command - not the internal asyncJob wrapper, whose
+// embedded context.Context can never be gob-registered.
+func TestBus_HandleAsync_WithFileQueue(t *testing.T) {
+	q, err := NewFileQueue(filepath.Join(t.TempDir(), "queue.log"), GobCodec{})
+	if err != nil {
+		t.Fatalf("NewFileQueue: %v", err)
+	}
+	defer q.Close()
+
+	bus := NewBus()
+	bus.WorkerPoolSize(1)
+	bus.Queue(q)
+
+	handled := make(chan fileQueueCmd, 1)
+	hdl := HandlerFunc[fileQueueCmd](func(ctx context.Context, cmd fileQueueCmd) error {
+		handled <- cmd
+		return nil
+	})
+	if err := bus.Initialize(hdl); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	if err := bus.HandleAsync(fileQueueCmd{Value: 7}); err != nil {
+		t.Fatalf("HandleAsync: %v", err)
+	}
+
+	select {
+	case cmd := <-handled:
+		if cmd.Value != 7 {
+			t.Fatalf("handled %+v, want Value 7", cmd)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("command was never handled through the FileQueue-backed bus")
+	}
+
+	bus.Shutdown(context.Background())
+}
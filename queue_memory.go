@@ -0,0 +1,53 @@
+package command
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// InMemoryQueue is the default Queue: an in-process buffered channel. It is
+// the fastest option but not durable - anything still queued is lost on
+// process restart.
+type InMemoryQueue struct {
+	ch  chan Command
+	len int32
+}
+
+// NewInMemoryQueue creates an InMemoryQueue with the given buffer size.
+func NewInMemoryQueue(buffer int) *InMemoryQueue {
+	return &InMemoryQueue{ch: make(chan Command, buffer)}
+}
+
+// Enqueue implements Queue.
+func (q *InMemoryQueue) Enqueue(ctx context.Context, cmd Command) error {
+	select {
+	case q.ch <- cmd:
+		atomic.AddInt32(&q.len, 1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Dequeue implements Queue. The returned Ack is a no-op: an in-memory queue
+// cannot redeliver a command once it has left the channel.
+func (q *InMemoryQueue) Dequeue(ctx context.Context) (Command, Ack, error) {
+	select {
+	case cmd := <-q.ch:
+		atomic.AddInt32(&q.len, -1)
+		return cmd, func(error) error { return nil }, nil
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+// Len implements Queue.
+func (q *InMemoryQueue) Len() int {
+	return int(atomic.LoadInt32(&q.len))
+}
+
+// Close implements Queue.
+func (q *InMemoryQueue) Close() error {
+	close(q.ch)
+	return nil
+}
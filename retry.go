@@ -0,0 +1,68 @@
+package command
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures how the bus retries an async Command whose Handler
+// returned an error, backing off exponentially between attempts. A command
+// that exhausts MaxAttempts is forwarded to the DeadLetterHandler instead of
+// being dropped.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of times a command is handled,
+	// including the first attempt. A command is dead-lettered once this
+	// many attempts have failed.
+	MaxAttempts int
+	// InitialDelay is the backoff before the first retry.
+	InitialDelay time.Duration
+	// Multiplier scales the delay after each retry. Values <= 1 keep the
+	// delay constant at InitialDelay.
+	Multiplier float64
+	// MaxDelay caps the computed backoff, regardless of Multiplier.
+	MaxDelay time.Duration
+	// Jitter randomizes each computed delay by up to this fraction of
+	// itself (e.g. 0.1 for +/-10%), to avoid synchronized retry storms.
+	// Zero disables jitter.
+	Jitter float64
+	// IsRetryable reports whether err should be retried. A nil IsRetryable
+	// retries every error.
+	IsRetryable func(err error) bool
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if p.IsRetryable == nil {
+		return true
+	}
+	return p.IsRetryable(err)
+}
+
+// delay returns the backoff to wait after the given attempt fails and
+// before the next one, where attempt is the attempt that just failed (the
+// first attempt is 1).
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	mult := p.Multiplier
+	if mult < 1 {
+		mult = 1
+	}
+	d := float64(p.InitialDelay) * math.Pow(mult, float64(attempt-1))
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (rand.Float64()*2 - 1)
+		if d < 0 {
+			d = 0
+		}
+	}
+	return time.Duration(d)
+}
+
+// DeadLetterHandler receives async commands that exhausted their
+// RetryPolicy, along with the number of attempts made and the last error
+// returned by the Handler, so operators can persist them for later replay.
+type DeadLetterHandler interface {
+	Handle(ctx context.Context, cmd Command, attempts int, lastErr error)
+}
@@ -0,0 +1,76 @@
+package command
+
+import (
+	"container/heap"
+	"context"
+	"time"
+)
+
+// retryItem is a command scheduled to be retried once when is reached.
+type retryItem struct {
+	when  time.Time
+	job   asyncJob
+	index int
+}
+
+// retryHeap is a min-heap of retryItem ordered by when, so the scheduler
+// always knows the next command due for retry without scanning.
+type retryHeap []*retryItem
+
+func (h retryHeap) Len() int           { return len(h) }
+func (h retryHeap) Less(i, j int) bool { return h[i].when.Before(h[j].when) }
+func (h retryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *retryHeap) Push(x interface{}) {
+	item := x.(*retryItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *retryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*h = old[:n-1]
+	return item
+}
+
+// retryScheduler re-enqueues retried commands onto jobs once their backoff
+// elapses, using a timer heap so a long backoff on one command never blocks
+// commands that don't need to wait.
+func (bus *Bus) retryScheduler(schedule <-chan *retryItem, jobs *jobQueue, done <-chan struct{}) {
+	h := &retryHeap{}
+	for {
+		var fire <-chan time.Time
+		var timer *time.Timer
+		if h.Len() > 0 {
+			timer = time.NewTimer(time.Until((*h)[0].when))
+			fire = timer.C
+		}
+
+		select {
+		case item, ok := <-schedule:
+			if timer != nil {
+				timer.Stop()
+			}
+			if !ok {
+				return
+			}
+			heap.Push(h, item)
+		case <-fire:
+			item := heap.Pop(h).(*retryItem)
+			_ = jobs.Enqueue(context.Background(), item.job)
+		case <-done:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		}
+	}
+}
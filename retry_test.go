@@ -0,0 +1,136 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type retryCmd struct{}
+
+// captureDeadLetterHandler records every command forwarded to it after its
+// RetryPolicy was exhausted.
+type captureDeadLetterHandler struct {
+	mu       sync.Mutex
+	attempts int
+	lastErr  error
+	received chan struct{}
+}
+
+func newCaptureDeadLetterHandler() *captureDeadLetterHandler {
+	return &captureDeadLetterHandler{received: make(chan struct{}, 1)}
+}
+
+func (h *captureDeadLetterHandler) Handle(ctx context.Context, cmd Command, attempts int, lastErr error) {
+	h.mu.Lock()
+	h.attempts = attempts
+	h.lastErr = lastErr
+	h.mu.Unlock()
+	h.received <- struct{}{}
+}
+
+// TestRetry_ExhaustedAttemptsForwardToDeadLetterHandler verifies that an
+// async command whose Handler always returns a retryable error is retried
+// up to RetryPolicy.MaxAttempts times, with every attempt beyond the first
+// going through the retryScheduler's backoff, and is then forwarded to the
+// DeadLetterHandler instead of being dropped.
+func TestRetry_ExhaustedAttemptsForwardToDeadLetterHandler(t *testing.T) {
+	var mu sync.Mutex
+	var attempts int
+	wantErr := errors.New("always fails")
+
+	bus := NewBus()
+	bus.WorkerPoolSize(1)
+	bus.RetryPolicy(RetryPolicy{
+		MaxAttempts:  3,
+		InitialDelay: time.Millisecond,
+	})
+	dlq := newCaptureDeadLetterHandler()
+	bus.DeadLetterHandler(dlq)
+
+	hdl := HandlerFunc[retryCmd](func(ctx context.Context, cmd retryCmd) error {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		return wantErr
+	})
+	if err := bus.Initialize(hdl); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	defer bus.Shutdown(context.Background())
+
+	if err := bus.HandleAsync(retryCmd{}); err != nil {
+		t.Fatalf("HandleAsync: %v", err)
+	}
+
+	select {
+	case <-dlq.received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("DeadLetterHandler was never invoked")
+	}
+
+	mu.Lock()
+	got := attempts
+	mu.Unlock()
+	if got != 3 {
+		t.Fatalf("Handler was invoked %d times, want 3 (MaxAttempts)", got)
+	}
+
+	dlq.mu.Lock()
+	defer dlq.mu.Unlock()
+	if dlq.attempts != 3 {
+		t.Fatalf("DeadLetterHandler saw attempts=%d, want 3", dlq.attempts)
+	}
+	if !errors.Is(dlq.lastErr, wantErr) {
+		t.Fatalf("DeadLetterHandler saw lastErr=%v, want %v", dlq.lastErr, wantErr)
+	}
+}
+
+// TestRetry_NonRetryableErrorSkipsStraightToDeadLetterHandler verifies that
+// a command whose error IsRetryable rejects is dead-lettered immediately,
+// without consuming any of its remaining MaxAttempts on retries.
+func TestRetry_NonRetryableErrorSkipsStraightToDeadLetterHandler(t *testing.T) {
+	var mu sync.Mutex
+	var attempts int
+	permanentErr := errors.New("permanent failure")
+
+	bus := NewBus()
+	bus.WorkerPoolSize(1)
+	bus.RetryPolicy(RetryPolicy{
+		MaxAttempts:  5,
+		InitialDelay: time.Millisecond,
+		IsRetryable:  func(err error) bool { return false },
+	})
+	dlq := newCaptureDeadLetterHandler()
+	bus.DeadLetterHandler(dlq)
+
+	hdl := HandlerFunc[retryCmd](func(ctx context.Context, cmd retryCmd) error {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		return permanentErr
+	})
+	if err := bus.Initialize(hdl); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	defer bus.Shutdown(context.Background())
+
+	if err := bus.HandleAsync(retryCmd{}); err != nil {
+		t.Fatalf("HandleAsync: %v", err)
+	}
+
+	select {
+	case <-dlq.received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("DeadLetterHandler was never invoked")
+	}
+
+	mu.Lock()
+	got := attempts
+	mu.Unlock()
+	if got != 1 {
+		t.Fatalf("Handler was invoked %d times, want 1 (non-retryable skips retries)", got)
+	}
+}
@@ -0,0 +1,102 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// asyncMetaKey is the context key runAsync uses to carry instrumentation
+// metadata - queue wait time and attempt number - down to the tracing
+// middleware, which otherwise has no visibility into the async queue.
+type asyncMetaKey struct{}
+
+// asyncMeta is instrumentation metadata attached to the context of an async
+// command, read by tracingMiddleware.
+type asyncMeta struct {
+	queueWait time.Duration
+	attempt   int
+}
+
+func withAsyncMeta(ctx context.Context, meta asyncMeta) context.Context {
+	return context.WithValue(ctx, asyncMetaKey{}, meta)
+}
+
+func asyncMetaFrom(ctx context.Context) (asyncMeta, bool) {
+	meta, ok := ctx.Value(asyncMetaKey{}).(asyncMeta)
+	return meta, ok
+}
+
+// WithTracer enables OpenTelemetry tracing: every Handle/HandleAsync call
+// starts a span named after the command's concrete type (or its Named
+// CommandName, if implemented), recording outcome, handler duration, queue
+// wait time and attempt number. It can only be set *before* the bus is
+// initialized.
+func (bus *Bus) WithTracer(tracer trace.Tracer) {
+	if !bus.isInitialized() {
+		bus.tracer = tracer
+	}
+}
+
+// tracingMiddleware wraps next in a span named by commandSpanName.
+func tracingMiddleware(tracer trace.Tracer) Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, cmd Command) error {
+			ctx, span := tracer.Start(ctx, commandSpanName(cmd))
+			defer span.End()
+
+			start := time.Now()
+			err := next(ctx, cmd)
+
+			attrs := []attribute.KeyValue{
+				attribute.String("command.outcome", outcome(err)),
+				attribute.Int64("command.duration_ms", time.Since(start).Milliseconds()),
+			}
+			if meta, ok := asyncMetaFrom(ctx); ok {
+				attrs = append(attrs,
+					attribute.Int64("command.queue_wait_ms", meta.queueWait.Milliseconds()),
+					attribute.Int("command.attempt", meta.attempt),
+				)
+			}
+			span.SetAttributes(attrs...)
+
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			} else {
+				span.SetStatus(codes.Ok, "")
+			}
+			return err
+		}
+	}
+}
+
+// commandSpanName names a span after cmd's Named.CommandName when
+// implemented, falling back to its concrete Go type.
+func commandSpanName(cmd Command) string {
+	if named, ok := cmd.(Named); ok {
+		return named.CommandName()
+	}
+	return reflect.TypeOf(cmd).String()
+}
+
+// outcome classifies err for metrics/tracing attributes: "ok", "panic",
+// "dropped" for a canceled or expired context, and "error" otherwise.
+func outcome(err error) string {
+	var panicErr *PanicError
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.As(err, &panicErr):
+		return "panic"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "dropped"
+	default:
+		return "error"
+	}
+}
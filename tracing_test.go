@@ -0,0 +1,92 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type tracedCmd struct{}
+
+// TestWithTracer_RecordsSpanPerCommand verifies that WithTracer starts one
+// span per Handle call, named after the command's concrete type, recording
+// its outcome and handler error.
+func TestWithTracer_RecordsSpanPerCommand(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	bus := NewBus()
+	bus.WithTracer(tp.Tracer("command-test"))
+	wantErr := errors.New("boom")
+	hdl := HandlerFunc[tracedCmd](func(ctx context.Context, cmd tracedCmd) error { return wantErr })
+	if err := bus.Initialize(hdl); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	defer bus.Shutdown(context.Background())
+
+	if err := bus.Handle(tracedCmd{}); !errors.Is(err, wantErr) {
+		t.Fatalf("Handle returned %v, want %v", err, wantErr)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if want := commandSpanName(tracedCmd{}); span.Name != want {
+		t.Fatalf("span name = %q, want %q", span.Name, want)
+	}
+
+	var gotOutcome string
+	for _, attr := range span.Attributes {
+		if string(attr.Key) == "command.outcome" {
+			gotOutcome = attr.Value.AsString()
+		}
+	}
+	if gotOutcome != "error" {
+		t.Fatalf("span command.outcome = %q, want %q", gotOutcome, "error")
+	}
+}
+
+// TestWithMeter_RecordsHandledCounter verifies that WithMeter records a
+// command.handled measurement, labeled by outcome, for every Handle call.
+func TestWithMeter_RecordsHandledCounter(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer mp.Shutdown(context.Background())
+
+	bus := NewBus()
+	bus.WithMeter(mp.Meter("command-test"))
+	hdl := HandlerFunc[tracedCmd](func(ctx context.Context, cmd tracedCmd) error { return nil })
+	if err := bus.Initialize(hdl); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	defer bus.Shutdown(context.Background())
+
+	if err := bus.Handle(tracedCmd{}); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+
+	found := false
+	for _, sm := range data.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == "command.handled" {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Fatal("command.handled metric was not recorded")
+	}
+}